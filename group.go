@@ -2,10 +2,47 @@ package lifespan
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DefaultGracefulStopTimeout bounds how long a single job's GracefulStop is given during
+// Group.Close if Group.GracefulStopTimeout is unset.
+const DefaultGracefulStopTimeout = 5 * time.Second
+
+// PreRunner is implemented by a Runnable that needs to prepare before the group starts
+// running. Group.Start invokes PreRun for every job that implements it, sequentially and
+// in dependency order, before any job is launched.
+type PreRunner interface {
+	PreRun(ctx context.Context) error
+}
+
+// Servable is implemented by a Runnable that would rather block on a long-running serve
+// loop than take a *LifeSpan directly. Group.Start calls Serve instead of Run for any job
+// that implements it, reporting a non-nil return as a fatal Error on the job's LifeSpan.
+type Servable interface {
+	Serve(ctx context.Context) error
+}
+
+// GracefulStopper is implemented by a Runnable that needs a chance to shut down cleanly
+// before its context is cancelled. Group.Close invokes GracefulStop, in reverse
+// dependency order, before falling back to cancelling every span's context.
+type GracefulStopper interface {
+	GracefulStop(ctx context.Context) error
+}
+
+// spanResult tracks the outcome of a single job launched by Group.Start.
+type spanResult struct {
+	mu   sync.Mutex
+	err  error
+	done chan struct{}
+}
+
 // Group defines a grouping of Runnable jobs and LifeSpans.
 type Group struct {
 	// UUID identifies a Group of Runnable. Useful for attributing logs and errors to a group.
@@ -14,6 +51,21 @@ type Group struct {
 	Jobs []Runnable
 	// Spans is a map of LifeSpans keyed by the LifeSpan's UUID.
 	Spans map[string]*LifeSpan
+
+	// GracefulStopTimeout bounds how long each job's GracefulStop is given during Close.
+	// DefaultGracefulStopTimeout is used if this is zero.
+	GracefulStopTimeout time.Duration
+
+	// TracerProvider, if set, is used to start each job's OpenTelemetry span instead of
+	// otel.GetTracerProvider(). Equivalent to passing WithTracerProvider to Run directly.
+	TracerProvider trace.TracerProvider
+
+	mu        sync.Mutex
+	deps      map[Runnable][]Runnable
+	order     []Runnable // dependency order Start launched jobs in
+	ids       []string   // span id per job in order, parallel to order
+	results   map[string]*spanResult
+	closeOnce sync.Once
 }
 
 // NewGroup returns a pointer to a *Group holding the Runnable jobs.
@@ -26,34 +78,238 @@ func NewGroup(jobs ...Runnable) *Group {
 	}
 }
 
-// Start executes the group of Jobs, storing each Job's LifeSpan in the Group structure.
+// DependsOn declares that job must complete PreRun, and be launched for Serve/Run, only
+// after every dep has. Start returns an error if the declared edges form a cycle.
+func (group *Group) DependsOn(job Runnable, deps ...Runnable) {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+	if group.deps == nil {
+		group.deps = make(map[Runnable][]Runnable)
+	}
+	group.deps[job] = append(group.deps[job], deps...)
+}
+
+// Start executes the group of Jobs, storing each job's LifeSpan in the Group structure.
+//
+// Start proceeds in phases: every PreRunner's PreRun is invoked sequentially in
+// dependency order, aborting and rolling back (via GracefulStop) any job already
+// prepared if one returns an error; then every job is launched concurrently, Servable
+// jobs under Serve and everything else under its plain Runnable.Run. A job reporting a
+// fatal Error triggers the same GracefulStop/Close sequence as an explicit Close call.
 func (group *Group) Start() error {
+	order, err := group.topoOrder()
+	if err != nil {
+		return err
+	}
 
-	// base context contains group_id
 	baseCtx := context.Background()
 	baseCtx = context.WithValue(baseCtx, groupIDKey, group.UUID)
 
-	for _, job := range group.Jobs {
+	var preRun []Runnable
+	for _, job := range order {
+		if pr, ok := job.(PreRunner); ok {
+			if err := pr.PreRun(baseCtx); err != nil {
+				group.rollback(preRun)
+				return fmt.Errorf("lifespan: PreRun failed: %w", err)
+			}
+		}
+		preRun = append(preRun, job)
+	}
+
+	group.mu.Lock()
+	group.order = order
+	group.results = make(map[string]*spanResult, len(order))
+	group.mu.Unlock()
+
+	for _, job := range order {
 		// build context per job containing job_id
 		id := uuid.New().String()
 		ctx := context.WithValue(baseCtx, jobIDKey, id)
+
+		res := &spanResult{done: make(chan struct{})}
+
+		j := job
 		span, _ := Run(ctx, func(ctx context.Context, span *LifeSpan) {
-			job.Run(ctx, span)
-		})
+			if s, ok := j.(Servable); ok {
+				if err := s.Serve(ctx); err != nil {
+					span.Fatal(ctx, err)
+				}
+				return
+			}
+			j.Run(ctx, span)
+		}, WithTracerProvider(group.TracerProvider), RunOptions{errListener: group.onFatal(res)})
+
+		group.mu.Lock()
 		group.Spans[id] = span
+		group.ids = append(group.ids, id)
+		group.results[id] = res
+		group.mu.Unlock()
+
+		go group.watch(span, res)
 	}
+
 	return nil
 }
 
-// Close will range over available spans calling each span's Close Method.
-func (group *Group) Close() {
-	for _, span := range group.Spans {
-		span.Close()
+// onFatal returns an errListener, registered on span's LifeSpan via Run before the job
+// goroutine starts, that records the first fatal error for res and triggers the group's
+// GracefulStop/Close sequence the moment one is observed. Registering it this way, rather
+// than having watch range over span.ErrBus itself, avoids a second consumer racing the
+// goroutine Run already spawns to forward ErrBus into DefaultCentralErrorBus.
+//
+// publishFull calls errListeners synchronously from span.Fatal, which runs on the job's
+// own goroutine; Close, in turn, blocks until that same goroutine has unwound. Calling
+// group.Close from here directly would have the job's span wait on its own completion and
+// deadlock until the 3-second ack timeout fired, so the close sequence is kicked off on its
+// own goroutine instead.
+func (group *Group) onFatal(res *spanResult) func(Error) {
+	return func(e Error) {
+		if !e.Fatal {
+			return
+		}
+		res.mu.Lock()
+		if res.err == nil {
+			res.err = e.Error
+		}
+		res.mu.Unlock()
+		group.closeOnce.Do(func() {
+			go group.Close(e.Error)
+		})
 	}
 }
 
+// watch blocks until span's job, including any restarts, has fully finished, then marks
+// res done so Wait can return. It waits on span.done rather than span.Ack for the same
+// reason LifeSpan.Close does: a job is free to send to Ack itself the moment it sees Sig,
+// and that send can race ahead of the state transition and cleanup Run still has left to
+// do, especially while a concurrent Group.Close (kicked off by onFatal) is in flight.
+func (group *Group) watch(span *LifeSpan, res *spanResult) {
+	defer close(res.done)
+	<-span.done
+}
+
+// rollback best-effort GracefulStops started, in reverse order, used when Start aborts
+// because a PreRun hook failed partway through.
+func (group *Group) rollback(started []Runnable) {
+	for i := len(started) - 1; i >= 0; i-- {
+		if gs, ok := started[i].(GracefulStopper); ok {
+			stopCtx, cancel := context.WithTimeout(context.Background(), group.stopTimeout())
+			_ = gs.GracefulStop(stopCtx)
+			cancel()
+		}
+	}
+}
+
+func (group *Group) stopTimeout() time.Duration {
+	if group.GracefulStopTimeout > 0 {
+		return group.GracefulStopTimeout
+	}
+	return DefaultGracefulStopTimeout
+}
+
+// Close stops the group: every GracefulStopper is given GracefulStopTimeout to shut down
+// cleanly, in reverse dependency order, then every span's context is cancelled with
+// cause. Any GracefulStop or span.Close errors are joined and returned.
+func (group *Group) Close(cause error) error {
+	group.mu.Lock()
+	order := append([]Runnable(nil), group.order...)
+	ids := append([]string(nil), group.ids...)
+	group.mu.Unlock()
+
+	var errs []error
+
+	for i := len(order) - 1; i >= 0; i-- {
+		if gs, ok := order[i].(GracefulStopper); ok {
+			stopCtx, cancel := context.WithTimeout(context.Background(), group.stopTimeout())
+			if err := gs.GracefulStop(stopCtx); err != nil {
+				errs = append(errs, err)
+			}
+			cancel()
+		}
+	}
+
+	for i := len(ids) - 1; i >= 0; i-- {
+		if span, ok := group.Spans[ids[i]]; ok {
+			if err := span.Close(cause); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CloseNormal closes every span in the group with ErrShutdown as the cancellation cause.
+func (group *Group) CloseNormal() error {
+	return group.Close(ErrShutdown)
+}
+
+// Wait blocks until every span's job in the group has finished, then returns a fatal
+// error reported by one of them, if any occurred. When several spans report a fatal
+// error, which one is returned is not defined: it is neither the earliest reported nor
+// tied to registration order.
+func (group *Group) Wait() error {
+	group.mu.Lock()
+	results := make([]*spanResult, 0, len(group.results))
+	for _, r := range group.results {
+		results = append(results, r)
+	}
+	group.mu.Unlock()
+
+	var firstErr error
+	for _, r := range results {
+		<-r.done
+		r.mu.Lock()
+		err := r.err
+		r.mu.Unlock()
+		if firstErr == nil && err != nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 // GetLifeSpanByID returns a pointer to the LifeSpan associated with the given uuid.
 // returns nil if non exists.
 func (group *Group) GetLifeSpanByID(uuid string) *LifeSpan {
 	return group.Spans[uuid]
 }
+
+// topoOrder returns group.Jobs ordered so that every job appears after the deps declared
+// for it via DependsOn, preserving registration order among jobs with no relative
+// ordering constraint. It returns an error if the declared edges contain a cycle.
+func (group *Group) topoOrder() ([]Runnable, error) {
+	group.mu.Lock()
+	deps := group.deps
+	group.mu.Unlock()
+
+	resolved := make(map[Runnable]bool, len(group.Jobs))
+	order := make([]Runnable, 0, len(group.Jobs))
+
+	for len(order) < len(group.Jobs) {
+		progressed := false
+		for _, job := range group.Jobs {
+			if resolved[job] {
+				continue
+			}
+			ready := true
+			for _, dep := range deps[job] {
+				if !resolved[dep] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			order = append(order, job)
+			resolved[job] = true
+			progressed = true
+		}
+		if !progressed {
+			return nil, errors.New("lifespan: dependency cycle detected in Group")
+		}
+	}
+
+	return order, nil
+}
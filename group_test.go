@@ -0,0 +1,100 @@
+package lifespan_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jharshman/lifespan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder collects events from concurrently running jobs.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// phasedJob is a Runnable that also implements PreRunner, Servable, and GracefulStopper
+// so Group.Start exercises every phase.
+type phasedJob struct {
+	name     string
+	events   *eventRecorder
+	serveErr error
+}
+
+func (j *phasedJob) PreRun(ctx context.Context) error {
+	j.events.record(j.name + ":prerun")
+	return nil
+}
+
+func (j *phasedJob) Serve(ctx context.Context) error {
+	j.events.record(j.name + ":serve")
+	<-ctx.Done()
+	return j.serveErr
+}
+
+func (j *phasedJob) GracefulStop(ctx context.Context) error {
+	j.events.record(j.name + ":stop")
+	return nil
+}
+
+func (j *phasedJob) Run(ctx context.Context, span *lifespan.LifeSpan) {}
+
+// failingJob is a Servable whose Serve returns an error immediately, used to exercise the
+// fatal-error-triggers-Close path without needing an explicit Close call.
+type failingJob struct {
+	err error
+}
+
+func (j *failingJob) Serve(ctx context.Context) error { return j.err }
+func (j *failingJob) Run(ctx context.Context, span *lifespan.LifeSpan) {}
+
+func Test_GroupPhasedOrchestration(t *testing.T) {
+
+	events := &eventRecorder{}
+
+	upstream := &phasedJob{name: "upstream", events: events}
+	downstream := &phasedJob{name: "downstream", events: events}
+
+	group := lifespan.NewGroup(downstream, upstream)
+	group.DependsOn(downstream, upstream)
+
+	require.NoError(t, group.Start())
+
+	assert.Eventually(t, func() bool {
+		return len(events.snapshot()) >= 4
+	}, time.Second, 10*time.Millisecond)
+
+	// both PreRun hooks must complete, upstream first, before either job is served.
+	got := events.snapshot()
+	assert.Equal(t, "upstream:prerun", got[0])
+	assert.Equal(t, "downstream:prerun", got[1])
+
+	require.NoError(t, group.CloseNormal())
+	assert.NoError(t, group.Wait())
+}
+
+func Test_GroupFatalErrorTriggersClose(t *testing.T) {
+
+	group := lifespan.NewGroup(&failingJob{err: errors.New("boom")})
+	require.NoError(t, group.Start())
+
+	err := group.Wait()
+	assert.ErrorContains(t, err, "boom")
+}
@@ -2,10 +2,18 @@ package lifespan
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -14,6 +22,18 @@ const (
 	groupIDKey        = "group_id"
 )
 
+var (
+	// ErrShutdown is the cause recorded when a LifeSpan is closed via CloseNormal or an
+	// unspecified Close(nil).
+	ErrShutdown = errors.New("lifespan: shutdown requested")
+	// ErrAckTimeout is the cause recorded when a job has not finished within the 3 second
+	// grace period after a Close request.
+	ErrAckTimeout = errors.New("lifespan: timeout waiting for acknowledgement")
+	// ErrAlreadyClosed is returned by Close when the LifeSpan is already stopping, stopped,
+	// or failed.
+	ErrAlreadyClosed = errors.New("lifespan: already closed")
+)
+
 // Runnable defines the behavior of a runnable task.
 type Runnable interface {
 	Run(ctx context.Context, span *LifeSpan)
@@ -21,40 +41,239 @@ type Runnable interface {
 
 // LifeSpan holds the communication channels and context for a runnable task.
 type LifeSpan struct {
-	// Sig and Ack are the primary control channels. Write to Sig to signal to close, and read from Ack to acknowledge.
+	// Sig and Ack are the primary control channels. Write to Sig to signal to close, and
+	// read from Ack to acknowledge. Neither Close nor Group.watch block on Ack any more
+	// (see done below); it remains for the job's own use.
 	Sig, Ack chan struct{}
 	//
 	ErrBus chan Error
 	// Default logger with extra context injected via Run.
 	Logger *slog.Logger
+
+	// cancel carries the cause of cancellation into the job's context, allowing a job
+	// blocked on ctx.Done() to recover the reason via context.Cause(ctx).
+	cancel context.CancelCauseFunc
+	// jobID and groupID are captured at Run time so internally generated errors (e.g. an
+	// ack timeout) can be attributed without needing the job's context.
+	jobID, groupID string
+
+	// otelSpan is the OpenTelemetry span covering the job's execution, started in Run.
+	otelSpan trace.Span
+
+	// done is closed by Run's goroutine once the LifeSpan has reached its terminal state
+	// (StateStopped or StateFailed), after every restart attempt has been exhausted. Close
+	// waits on done rather than on Ack: a job is free to send to Ack itself the moment it
+	// sees Sig (see the Sig/Ack doc above), and that send can race ahead of the state
+	// transition and cleanup Run still has left to do, especially across a restart. done
+	// has no such race because only Run's goroutine closes it, and only after setState has
+	// already landed the terminal transition.
+	done chan struct{}
+
+	// dispatchMu serializes a transition's state mutation together with its listener
+	// dispatch: setState and Close both hold it across the whole transition, not just the
+	// part guarded by mu, so two transitions (e.g. one driven by Close, one by Run's
+	// internal setState) never call listeners concurrently and listeners observe
+	// transitions in the order they actually happened.
+	dispatchMu sync.Mutex
+
+	// mu guards state, fatal, listeners, and errListeners.
+	mu    sync.Mutex
+	state State
+	// fatal records that Fatal was called during the current attempt. Fatal itself does not
+	// transition to StateFailed: the run loop reads and clears fatal once the attempt
+	// returns, alongside the recovered-panic fatal result from runAttempt, so a job that
+	// reports fatal is restarted (or not) the same way a panicking one is, instead of
+	// visiting StateFailed and then bouncing back to StateRunning on the next attempt.
+	fatal        bool
+	listeners    []listener
+	nextListener int
+
+	// errListeners are notified synchronously, in publishFull, with every Error alongside
+	// it being sent on ErrBus. Unlike a second consumer of ErrBus, this can't race the
+	// goroutine Run spawns to forward ErrBus into DefaultCentralErrorBus: it's populated
+	// once at construction time, before that goroutine (or the job) ever runs. Used by
+	// Group to observe fatal errors without stealing messages from that goroutine.
+	errListeners []func(Error)
+}
+
+// State returns the LifeSpan's current lifecycle state.
+func (span *LifeSpan) State() State {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	return span.state
 }
 
-// Close will signal a runnable task to shutdown. If an acknowledgement is not given
-// by the runnable task after 3 seconds, Close will log a warning but otherwise
-// leave the task to handle cancellation according to its own implementation.
-func (span *LifeSpan) Close() {
+// AddListener registers fn to be called with the from and to states of every subsequent
+// transition. It returns a function that removes the listener.
+func (span *LifeSpan) AddListener(fn func(from, to State)) func() {
+	span.mu.Lock()
+	id := span.nextListener
+	span.nextListener++
+	span.listeners = append(span.listeners, listener{id: id, fn: fn})
+	span.mu.Unlock()
+
+	return func() {
+		span.mu.Lock()
+		defer span.mu.Unlock()
+		for i, l := range span.listeners {
+			if l.id == id {
+				span.listeners = append(span.listeners[:i], span.listeners[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// setState transitions the LifeSpan to the given state and notifies listeners registered
+// via AddListener. Listeners are invoked outside of mu, so they may safely call back into
+// the LifeSpan (e.g. span.State()), but under dispatchMu, so they never run concurrently
+// with the listener dispatch Close does for its own StateStopping transition.
+func (span *LifeSpan) setState(to State) {
+	span.dispatchMu.Lock()
+	defer span.dispatchMu.Unlock()
+
+	span.mu.Lock()
+	from := span.state
+	span.state = to
+	fns := make([]func(from, to State), len(span.listeners))
+	for i, l := range span.listeners {
+		fns[i] = l.fn
+	}
+	span.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(from, to)
+	}
+}
+
+// Close signals a runnable task to shutdown, recording cause as the reason the job's
+// context was cancelled, and drives the LifeSpan through StateStopping to StateStopped.
+// If the LifeSpan is already stopping, stopped, or failed, Close is a no-op that returns
+// ErrAlreadyClosed. If the runnable task has not finished (reached its terminal state)
+// 3 seconds after being signalled, Close will publish an Error tagged with ErrAckTimeout
+// to the ErrBus but otherwise leave the task to handle cancellation according to its own
+// implementation.
+func (span *LifeSpan) Close(cause error) error {
+	if cause == nil {
+		cause = ErrShutdown
+	}
+
+	span.dispatchMu.Lock()
+	span.mu.Lock()
+	if span.state == StateStopping || span.state == StateStopped || span.state == StateFailed {
+		span.mu.Unlock()
+		span.dispatchMu.Unlock()
+		return ErrAlreadyClosed
+	}
+	from := span.state
+	span.state = StateStopping
+	fns := make([]func(from, to State), len(span.listeners))
+	for i, l := range span.listeners {
+		fns[i] = l.fn
+	}
+	span.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(from, StateStopping)
+	}
+	span.dispatchMu.Unlock()
+
 	select {
 	case span.Sig <- struct{}{}:
+		span.cancel(cause)
 		select {
-		case <-span.Ack:
-			return
+		case <-span.done:
+			return nil
 		case <-time.After(3 * time.Second):
-			slog.Warn("timeout waiting for acknowledgement")
+			span.publish(fmt.Errorf("%w: %w", ErrAckTimeout, cause))
+			return nil
 		}
 	default:
+		span.cancel(cause)
 		slog.Warn("unable to send signal")
+		return nil
 	}
 }
 
+// CloseNormal closes the LifeSpan with ErrShutdown as the cancellation cause, indicating
+// an orderly shutdown as opposed to a timeout or application-provided error.
+func (span *LifeSpan) CloseNormal() error {
+	return span.Close(ErrShutdown)
+}
+
+// RunOptions configures optional behavior for Run.
+type RunOptions struct {
+	// PanicHandler, if set, is invoked after a job panic has been recovered and published
+	// to the ErrBus as a fatal Error. It receives the recovered value, the stack trace
+	// captured at the point of the panic, and the LifeSpan the job was running under.
+	PanicHandler func(recovered any, stack []byte, span *LifeSpan)
+	// Restart, if set, re-invokes job when it returns before Close has been requested, or
+	// when it panics, instead of moving the LifeSpan straight to StateStopped/StateFailed.
+	// StateFailed is only entered once the policy's attempts are exhausted. See
+	// WithRestart, NeverRestart, and AlwaysRestart.
+	Restart *RestartPolicy
+	// TracerProvider, if set, is used to start the job's OpenTelemetry span instead of
+	// otel.GetTracerProvider(). See WithTracerProvider.
+	TracerProvider trace.TracerProvider
+
+	// errListener, if set, is registered on the LifeSpan's errListeners before the job
+	// goroutine starts. It's internal plumbing for Group, which needs to observe fatal
+	// errors without adding a second consumer of ErrBus alongside the one Run registers
+	// with DefaultCentralErrorBus.
+	errListener func(Error)
+}
+
+// WithTracerProvider returns a RunOptions value that has Run start the job's span from tp
+// instead of the global otel.GetTracerProvider().
+func WithTracerProvider(tp trace.TracerProvider) RunOptions {
+	return RunOptions{TracerProvider: tp}
+}
+
 // Run runs the passed in job and returns a pointer to a LifeSpan.
 // If groupID is empty, no group_id attribute will be added to the logger.
-func Run(ctx context.Context, job func(ctx context.Context, span *LifeSpan)) (*LifeSpan, error) {
+// A panic in job is recovered rather than left to crash the process: it is published to
+// the ErrBus as a fatal Error carrying the stack trace, and opts.PanicHandler, if
+// provided, is invoked with the same information. A job that calls span.Fatal instead of
+// panicking is treated the same way. Absent a restart policy the LifeSpan moves straight
+// to StateFailed; with one, the panic or Fatal call is treated like any other early return
+// and subject to opts.Restart.
+func Run(ctx context.Context, job func(ctx context.Context, span *LifeSpan), opts ...RunOptions) (*LifeSpan, error) {
+
+	var o RunOptions
+	for _, opt := range opts {
+		if opt.PanicHandler != nil {
+			o.PanicHandler = opt.PanicHandler
+		}
+		if opt.Restart != nil {
+			o.Restart = opt.Restart
+		}
+		if opt.TracerProvider != nil {
+			o.TracerProvider = opt.TracerProvider
+		}
+		if opt.errListener != nil {
+			o.errListener = opt.errListener
+		}
+	}
 
 	// if the context does not contain a job_id then create and set one.
 	if _, ok := ctx.Value(jobIDKey).(string); !ok {
 		ctx = context.WithValue(ctx, jobIDKey, uuid.New().String())
 	}
 
+	// derive a cancellation-with-cause context so a job blocked on ctx.Done() can recover
+	// the reason for cancellation via context.Cause(ctx), whether that's Close, a
+	// parent-context cancellation, or an application-provided error.
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	tp := o.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	ctx, otelSpan := tp.Tracer("lifespan").Start(ctx, "lifespan.job", trace.WithAttributes(
+		attribute.String("job_id", JobIDFromContext(ctx)),
+		attribute.String("group_id", GroupIDFromContext(ctx)),
+	))
+
 	// create a unique channel for the LifeSpan's errors and register it with the DefaultCentralErrorBus.
 	errChan := make(chan Error, defaultBufferSize)
 	DefaultCentralErrorBus.Register(errChan)
@@ -68,27 +287,151 @@ func Run(ctx context.Context, job func(ctx context.Context, span *LifeSpan)) (*L
 	)
 
 	span := &LifeSpan{
-		Sig:    make(chan struct{}, 1),
-		Ack:    make(chan struct{}, 1),
-		ErrBus: errChan,
-		Logger: l,
+		Sig:      make(chan struct{}, 1),
+		Ack:      make(chan struct{}, 1),
+		ErrBus:   errChan,
+		Logger:   l,
+		cancel:   cancel,
+		jobID:    JobIDFromContext(ctx),
+		groupID:  GroupIDFromContext(ctx),
+		otelSpan: otelSpan,
+		done:     make(chan struct{}),
 	}
+	if o.errListener != nil {
+		span.errListeners = append(span.errListeners, o.errListener)
+	}
+
+	span.setState(StateStarting)
 
 	go func() {
+		// defer close(span.done) is registered first so it runs last, after the
+		// state-transition defer below has landed the LifeSpan in its terminal state: Close
+		// and Group.watch both wait on done, so they only unblock once span.State() is
+		// observably StateStopped/StateFailed. close(span.Ack) runs earlier in this chain
+		// and is not load-bearing for either any more; it remains for the job's own use,
+		// the way the Sig/Ack doc above describes.
+		defer close(span.done)
 		defer close(span.Ack)
+		defer otelSpan.End()
 		defer close(span.ErrBus)
-		job(ctx, span)
+		defer cancel(ErrShutdown)
+		defer func() {
+			if span.State() != StateFailed {
+				span.setState(StateStopped)
+			}
+		}()
+
+		attempt := 0
+		for {
+			span.setState(StateRunning)
+			started := time.Now()
+			fatal := span.runAttempt(ctx, job, o.PanicHandler)
+
+			// a call to span.Fatal during this attempt marks it fatal the same way a
+			// recovered panic does, so it's subject to the same restart decision below
+			// rather than jumping straight to StateFailed and then bouncing back to
+			// StateRunning on the next attempt.
+			span.mu.Lock()
+			fatal = fatal || span.fatal
+			span.fatal = false
+			span.mu.Unlock()
+
+			// a job is free to send to Ack itself once it sees Sig (see the Sig/Ack doc
+			// above); Ack is buffered 1, so with a restart policy in play that send must be
+			// drained here or the next attempt's own send to Ack would block forever.
+			select {
+			case <-span.Ack:
+			default:
+			}
+
+			if ctx.Err() != nil || o.Restart == nil || o.Restart.MaxAttempts < 0 {
+				if fatal {
+					span.setState(StateFailed)
+				}
+				break
+			}
+
+			if o.Restart.ResetAfter > 0 && time.Since(started) >= o.Restart.ResetAfter {
+				attempt = 0
+			}
+
+			if o.Restart.exhausted(attempt) {
+				span.setState(StateFailed)
+				break
+			}
+
+			d := o.Restart.delay(attempt)
+			attempt++
+			span.Logger.Info("restarting job", slog.Int("attempt", attempt), slog.Duration("delay", d))
+
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
 	}()
 
 	return span, nil
 }
 
-// Error shortcuts publishing to the ErrBus and inserts the JobID, GroupID, and timestamp into the Error.
+// runAttempt invokes job once, recovering a panic into a fatal Error on the ErrBus and
+// forwarding it to panicHandler if provided. It reports whether the attempt ended fatally.
+func (span *LifeSpan) runAttempt(ctx context.Context, job func(ctx context.Context, span *LifeSpan), panicHandler func(recovered any, stack []byte, span *LifeSpan)) (fatal bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			fatal = true
+			err := fmt.Errorf("panic: %v", r)
+			span.otelSpan.RecordError(err)
+			span.otelSpan.SetStatus(codes.Error, err.Error())
+			span.publishFull(Error{
+				Error: err,
+				Fatal: true,
+				Stack: stack,
+			})
+			if panicHandler != nil {
+				panicHandler(r, stack, span)
+			}
+		}
+	}()
+
+	job(ctx, span)
+	return fatal
+}
+
+// Error shortcuts publishing to the ErrBus and inserts the JobID, GroupID, and timestamp
+// into the Error. It also records err on the job's OpenTelemetry span so a trace shows
+// the failure at the point it occurred, not only in the aggregated error bus.
 func (span *LifeSpan) Error(ctx context.Context, err error) {
-	e := Error{
-		Error:     err,
-		Timestamp: time.Now().UTC(),
+	span.otelSpan.RecordError(err)
+
+	e := Error{Error: err}
+	if jid, ok := ctx.Value(jobIDKey).(string); ok {
+		e.JobID = jid
 	}
+	if gid, ok := ctx.Value(groupIDKey).(string); ok {
+		e.GroupID = gid
+	}
+
+	span.publishFull(e)
+}
+
+// Fatal publishes err to the ErrBus tagged Fatal and marks the current attempt fatal. Use
+// Fatal instead of Error when the job cannot continue. The job's OpenTelemetry span is
+// marked codes.Error with err recorded against it. The LifeSpan reaches StateFailed once
+// Run's restart loop decides this attempt isn't getting another try, the same as a
+// recovered panic would; it is not synchronous with this call.
+func (span *LifeSpan) Fatal(ctx context.Context, err error) {
+	span.mu.Lock()
+	span.fatal = true
+	span.mu.Unlock()
+	span.otelSpan.RecordError(err)
+	span.otelSpan.SetStatus(codes.Error, err.Error())
+
+	e := Error{Error: err, Fatal: true}
 	if jid, ok := ctx.Value(jobIDKey).(string); ok {
 		e.JobID = jid
 	}
@@ -96,6 +439,31 @@ func (span *LifeSpan) Error(ctx context.Context, err error) {
 		e.GroupID = gid
 	}
 
+	span.publishFull(e)
+}
+
+// publish sends err to the ErrBus, tagging it with the JobID and GroupID captured at Run time.
+// It is used internally (e.g. for an ack timeout) where the job's own context is not available.
+func (span *LifeSpan) publish(err error) {
+	span.publishFull(Error{
+		JobID:   span.jobID,
+		GroupID: span.groupID,
+		Error:   err,
+	})
+}
+
+// publishFull sets the Timestamp on e, notifies errListeners, and sends it to the ErrBus.
+func (span *LifeSpan) publishFull(e Error) {
+	e.Timestamp = time.Now().UTC()
+
+	span.mu.Lock()
+	fns := make([]func(Error), len(span.errListeners))
+	copy(fns, span.errListeners)
+	span.mu.Unlock()
+	for _, fn := range fns {
+		fn(e)
+	}
+
 	span.ErrBus <- e
 }
 
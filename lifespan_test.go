@@ -37,12 +37,14 @@ func Test_Run(t *testing.T) {
 	assert.NotNil(t, span.ErrBus)
 
 	// close the span
-	span.Close()
+	span.CloseNormal()
 
 }
 
 func Test_RunWithErrorBus(t *testing.T) {
 
+	wantErr := errors.New("test error")
+
 	jobfunc := func(ctx context.Context, span *lifespan.LifeSpan) {
 		span.Logger.Info("starting job")
 	LOOP:
@@ -51,7 +53,7 @@ func Test_RunWithErrorBus(t *testing.T) {
 			case <-ctx.Done():
 				break LOOP
 			case <-span.Sig:
-				span.Error(ctx, errors.New("test error"))
+				span.Error(ctx, wantErr)
 			}
 		}
 		span.Ack <- struct{}{}
@@ -70,6 +72,14 @@ func Test_RunWithErrorBus(t *testing.T) {
 			case <-span.Sig:
 				break LOOP
 			case msg := <-sub:
+				// DefaultCentralErrorBus is a package-wide singleton shared with every
+				// other test in this package; a straggler from an earlier test (e.g.
+				// Test_GroupFatalErrorTriggersClose's fatal error) can still be in flight
+				// through CentralMessageBus's forwarding goroutine when this test starts,
+				// so only count the errors this test actually triggered.
+				if msg.Error != wantErr {
+					continue LOOP
+				}
 				fmt.Println(msg)
 				errorCount.Add(1)
 			}
@@ -97,10 +107,11 @@ LOOP:
 	// kill span1 and span2 with cancel function
 	cancel()
 	// kill span3
-	span3.Close()
+	span3.CloseNormal()
 
-	// close central error bus
-	lifespan.DefaultCentralErrorBus.Close()
+	// DefaultCentralErrorBus is a package-wide singleton shared with every other test in
+	// this package; closing it here would make every later Run in the suite panic inside
+	// Register with "CentralMessageBus[T] is already closed", so leave it open.
 
 	assert.Equal(t, int32(10), errorCount.Load())
 
@@ -3,15 +3,15 @@ package lifespan
 import (
 	"context"
 	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// trace_id and span_id metadata keys, populated from the context's active OTel span.
+// defaultBufferSize, jobIDKey, and groupIDKey are declared in lifespan.go.
 const (
-	// defaultBufferSize provides a sane default for the underlying LogBus.
-	defaultBufferSize = 1024
-
-	// job_id and group_id attribute keys
-	jobIDKey   = "job_id"
-	groupIDKey = "group_id"
+	traceIDKey = "trace_id"
+	spanIDKey  = "span_id"
 )
 
 // Logger implements of log/slog Handler.
@@ -68,6 +68,12 @@ func (l *Logger) Handle(ctx context.Context, r slog.Record) error {
 		log.GroupID = gid
 	}
 
+	// correlate with the active OTel span, if any, so log records can be joined with traces.
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		log.Metadata[traceIDKey] = sc.TraceID().String()
+		log.Metadata[spanIDKey] = sc.SpanID().String()
+	}
+
 	// Process the logger's stored attributes first (from WithAttrs calls)
 	for _, attr := range l.attrs {
 		v := attr.Value.Resolve()
@@ -12,6 +12,26 @@ type Error struct {
 	GroupID   string
 	Error     error
 	Timestamp time.Time
+	// Fatal indicates the job cannot continue (e.g. a recovered panic or an explicit
+	// span.Fatal call). The owning LifeSpan reaches StateFailed once Run's restart loop
+	// decides the attempt isn't getting another try, which is not necessarily immediate.
+	Fatal bool
+	// Stack holds the stack trace captured at the point of a recovered panic. It is nil
+	// for non-panic errors.
+	Stack []byte
+}
+
+// Log is a message that can be sent via a lifespan MessageBus.
+// This type contains a structured log record, as assembled by Logger.Handle, along with
+// the same JobID/GroupID metadata attached to Error so log records can be attributed back
+// to the LifeSpan that emitted them.
+type Log struct {
+	JobID     string
+	GroupID   string
+	Timestamp time.Time
+	Msg       string
+	Level     string
+	Metadata  map[string]any
 }
 
 // MessageBus defines behavior for a generic message bus.
@@ -32,6 +52,11 @@ type CentralMessageBus[T any] struct {
 	bus    chan T
 }
 
+// ErrorBus and LogBus are the CentralMessageBus instantiations used for Errors and Logs,
+// respectively. See NewErrorBus, NewLogBus, and DefaultCentralErrorBus.
+type ErrorBus = CentralMessageBus[Error]
+type LogBus = CentralMessageBus[Log]
+
 var DefaultCentralErrorBus = NewCentralMessageBus[Error](defaultBufferSize)
 
 func NewCentralMessageBus[T any](bufferSize int64) *CentralMessageBus[T] {
@@ -40,6 +65,24 @@ func NewCentralMessageBus[T any](bufferSize int64) *CentralMessageBus[T] {
 	}
 }
 
+// NewErrorBus returns a new ErrorBus. If bufferSize is less than defaultBufferSize, it
+// defaults to defaultBufferSize.
+func NewErrorBus(bufferSize int64) *ErrorBus {
+	if bufferSize < defaultBufferSize {
+		bufferSize = defaultBufferSize
+	}
+	return NewCentralMessageBus[Error](bufferSize)
+}
+
+// NewLogBus returns a new LogBus. If bufferSize is less than defaultBufferSize, it defaults
+// to defaultBufferSize.
+func NewLogBus(bufferSize int64) *LogBus {
+	if bufferSize < defaultBufferSize {
+		bufferSize = defaultBufferSize
+	}
+	return NewCentralMessageBus[Log](bufferSize)
+}
+
 func (cb *CentralMessageBus[T]) Register(ch <-chan T) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
@@ -0,0 +1,38 @@
+package lifespan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jharshman/lifespan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RunRecoversPanic(t *testing.T) {
+
+	handled := make(chan struct{}, 1)
+
+	span, err := lifespan.Run(context.Background(), func(ctx context.Context, span *lifespan.LifeSpan) {
+		panic("boom")
+	}, lifespan.RunOptions{
+		PanicHandler: func(recovered any, stack []byte, span *lifespan.LifeSpan) {
+			assert.Equal(t, "boom", recovered)
+			assert.NotEmpty(t, stack)
+			handled <- struct{}{}
+		},
+	})
+	require.NoError(t, err)
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("PanicHandler was not invoked")
+	}
+
+	// PanicHandler runs before Run's loop records StateFailed, so wait for the transition.
+	assert.Eventually(t, func() bool {
+		return span.State() == lifespan.StateFailed
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,68 @@
+package lifespan
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RestartPolicy configures how Run restarts a job that returns before Close has been
+// requested, or that encounters a non-fatal terminal error. The delay before the Nth
+// restart is d = min(Max, Initial * Multiplier^attempt), randomized by +/- JitterFraction/2.
+type RestartPolicy struct {
+	// MaxAttempts bounds the number of restarts. 0 means unlimited; negative disables
+	// restarts entirely (see NeverRestart), since 0 is already taken by "unlimited".
+	MaxAttempts int
+	// Initial is the delay before the first restart attempt.
+	Initial time.Duration
+	// Max caps the computed delay, regardless of attempt count.
+	Max time.Duration
+	// Multiplier grows the delay between attempts.
+	Multiplier float64
+	// JitterFraction randomizes the computed delay by +/- JitterFraction/2.
+	JitterFraction float64
+	// ResetAfter is how long a job must stay running before its attempt counter resets to
+	// zero. A zero value never resets the counter.
+	ResetAfter time.Duration
+}
+
+// NeverRestart disables restarts: a job that returns on its own is treated the same as
+// before this policy existed, and a panic or fatal error goes straight to StateFailed.
+// MaxAttempts is negative rather than 0, which already means "unlimited"; Run treats any
+// policy with a negative MaxAttempts as equivalent to no restart policy at all.
+var NeverRestart = RestartPolicy{MaxAttempts: -1}
+
+// AlwaysRestart restarts a job unconditionally with a modest exponential backoff.
+var AlwaysRestart = RestartPolicy{
+	MaxAttempts:    0,
+	Initial:        time.Second,
+	Max:            30 * time.Second,
+	Multiplier:     2,
+	JitterFraction: 0.2,
+	ResetAfter:     time.Minute,
+}
+
+// WithRestart returns a RunOptions value configured to restart a job under policy.
+func WithRestart(policy RestartPolicy) RunOptions {
+	return RunOptions{Restart: &policy}
+}
+
+// exhausted reports whether attempt (0-indexed) has used up MaxAttempts.
+func (p RestartPolicy) exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
+
+// delay computes the backoff duration before the given attempt (0-indexed).
+func (p RestartPolicy) delay(attempt int) time.Duration {
+	d := float64(p.Initial) * math.Pow(p.Multiplier, float64(attempt))
+	if p.Max > 0 && d > float64(p.Max) {
+		d = float64(p.Max)
+	}
+	if p.JitterFraction > 0 {
+		d *= 1 + rand.Float64()*p.JitterFraction - p.JitterFraction/2
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
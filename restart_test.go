@@ -0,0 +1,55 @@
+package lifespan_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jharshman/lifespan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RunWithRestart(t *testing.T) {
+
+	var runs atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	span, err := lifespan.Run(ctx, func(ctx context.Context, span *lifespan.LifeSpan) {
+		runs.Add(1)
+	}, lifespan.WithRestart(lifespan.RestartPolicy{
+		MaxAttempts: 2,
+		Initial:     10 * time.Millisecond,
+		Multiplier:  1,
+	}))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return runs.Load() == 3 // the initial run plus 2 restarts
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return span.State() == lifespan.StateFailed
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_RunWithNeverRestart(t *testing.T) {
+
+	var runs atomic.Int32
+
+	span, err := lifespan.Run(context.Background(), func(ctx context.Context, span *lifespan.LifeSpan) {
+		runs.Add(1)
+	}, lifespan.WithRestart(lifespan.NeverRestart))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return span.State() == lifespan.StateStopped
+	}, time.Second, 10*time.Millisecond)
+
+	// give a would-be restart a chance to happen before asserting it never did.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+}
@@ -0,0 +1,50 @@
+package lifespan
+
+import "fmt"
+
+// State represents a LifeSpan's position in its lifecycle.
+type State int
+
+const (
+	// StateNew is the state of a LifeSpan before its job has been started.
+	StateNew State = iota
+	// StateStarting indicates Run is preparing the job for execution.
+	StateStarting
+	// StateRunning indicates the job is executing.
+	StateRunning
+	// StateStopping indicates Close has been requested and the job is shutting down.
+	StateStopping
+	// StateStopped indicates the job has returned, either after Close or on its own, and
+	// will not be restarted.
+	StateStopped
+	// StateFailed is a terminal state entered when the job panics or reports a fatal error
+	// and is not restarted, or when a restart policy's attempts are exhausted.
+	StateFailed
+)
+
+// String returns the human readable name of the State.
+func (s State) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// listener pairs a registered transition callback with an id so AddListener's removal
+// function can find and remove it later.
+type listener struct {
+	id int
+	fn func(from, to State)
+}
@@ -0,0 +1,38 @@
+package lifespan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jharshman/lifespan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StateTransitions(t *testing.T) {
+
+	var transitions []lifespan.State
+
+	span, err := lifespan.Run(context.Background(), func(ctx context.Context, span *lifespan.LifeSpan) {
+		<-span.Sig
+		span.Ack <- struct{}{}
+	})
+	require.NoError(t, err)
+
+	remove := span.AddListener(func(from, to lifespan.State) {
+		transitions = append(transitions, to)
+	})
+	defer remove()
+
+	assert.Eventually(t, func() bool {
+		return span.State() == lifespan.StateRunning
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, span.CloseNormal())
+	assert.Equal(t, lifespan.StateStopped, span.State())
+	assert.Contains(t, transitions, lifespan.StateStopped)
+
+	// a second Close is a no-op and reports ErrAlreadyClosed.
+	assert.ErrorIs(t, span.CloseNormal(), lifespan.ErrAlreadyClosed)
+}